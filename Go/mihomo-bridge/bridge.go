@@ -23,8 +23,8 @@ import (
 )
 
 var (
-	mu      sync.Mutex
-	running bool
+	mu          sync.Mutex
+	running     bool
 	tunFdGlobal int32 = -1
 )
 
@@ -99,6 +99,9 @@ func StartProxy() error {
 	debug.FreeOSMemory()
 
 	running = true
+	startGeoUpdater()
+	startSinks()
+	startSubscriptionUpdater()
 	log.Infoln("Mihomo proxy engine started")
 	return nil
 }
@@ -145,6 +148,9 @@ func StartWithExternalController(addr, secret string) error {
 	debug.FreeOSMemory()
 
 	running = true
+	startGeoUpdater()
+	startSinks()
+	startSubscriptionUpdater()
 	log.Infoln("Mihomo proxy engine started with external controller at %s", addr)
 	return nil
 }
@@ -161,6 +167,9 @@ func StopProxy() {
 	}
 
 	executor.Shutdown()
+	stopGeoUpdater()
+	stopSinks()
+	stopSubscriptionUpdater()
 
 	running = false
 	tunFdGlobal = -1
@@ -0,0 +1,217 @@
+// Copyright (c) 2026 Max Lv <max.c.lv@gmail.com>
+//
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package bridge
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
+	"github.com/metacubex/mihomo/constant"
+	"github.com/metacubex/mihomo/log"
+)
+
+// ErrGeoUpdateSkip is returned by UpdateGeoDatabases when an update is already in flight.
+var ErrGeoUpdateSkip = errors.New("geo database update already in progress")
+
+// GeoUpdateCallback is invoked on the Swift side after a successful geo database
+// update, so the UI can trigger a config reload.
+type GeoUpdateCallback interface {
+	OnGeoUpdateSuccess()
+}
+
+const (
+	geoIPMetaDBName  = "geoip.metadb"
+	geoSiteDatName   = "geosite.dat"
+	geoIPDatName     = "geoip.dat"
+	geoLastUpdateTag = ".geo-last-update"
+)
+
+var (
+	geoUpdating      atomic.Bool
+	geoTicker        *time.Ticker
+	geoStopCh        chan struct{}
+	geoOnSuccess     GeoUpdateCallback
+	geoIntervalHours = 24
+
+	geoIPMetaDBURL = "https://github.com/MetaCubeX/meta-rules-dat/releases/download/latest/geoip.metadb"
+	geoSiteDatURL  = "https://github.com/MetaCubeX/meta-rules-dat/releases/download/latest/geosite.dat"
+	geoIPDatURL    = "https://github.com/MetaCubeX/meta-rules-dat/releases/download/latest/geoip.dat"
+)
+
+// SetGeoURLs overrides the download URLs for the geo databases. Empty strings
+// leave the corresponding URL unchanged.
+func SetGeoURLs(geoIPMetaDB, geoSite, geoIPDat string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if geoIPMetaDB != "" {
+		geoIPMetaDBURL = geoIPMetaDB
+	}
+	if geoSite != "" {
+		geoSiteDatURL = geoSite
+	}
+	if geoIPDat != "" {
+		geoIPDatURL = geoIPDat
+	}
+}
+
+// RegisterGeoUpdater schedules automatic geo database refreshes every intervalHours
+// and stores onSuccess to be invoked after each successful update. The ticker
+// goroutine is started by StartProxy and stopped by StopProxy; it reads the
+// interval when it starts, so an interval set while the engine is running takes
+// effect at the next StartProxy. onSuccess applies from the next update either way.
+func RegisterGeoUpdater(intervalHours int, onSuccess GeoUpdateCallback) {
+	if intervalHours <= 0 {
+		intervalHours = 24
+	}
+	mu.Lock()
+	geoIntervalHours = intervalHours
+	geoOnSuccess = onSuccess
+	mu.Unlock()
+}
+
+// UpdateGeoDatabases downloads the latest geoip.metadb, geosite.dat, and geoip.dat
+// into the Mihomo home directory, atomically renaming each into place. It returns
+// ErrGeoUpdateSkip if an update is already running.
+func UpdateGeoDatabases() error {
+	if !geoUpdating.CompareAndSwap(false, true) {
+		return ErrGeoUpdateSkip
+	}
+	defer geoUpdating.Store(false)
+
+	mu.Lock()
+	metaDBURL, siteURL, ipDatURL := geoIPMetaDBURL, geoSiteDatURL, geoIPDatURL
+	mu.Unlock()
+
+	homeDir := constant.Path.HomeDir()
+	files := []struct {
+		name string
+		url  string
+	}{
+		{geoIPMetaDBName, metaDBURL},
+		{geoSiteDatName, siteURL},
+		{geoIPDatName, ipDatURL},
+	}
+
+	for _, f := range files {
+		if err := downloadToFile(f.url, filepath.Join(homeDir, f.name)); err != nil {
+			log.Warnln("geo database update failed for %s: %s", f.name, err.Error())
+			return err
+		}
+	}
+
+	touchLastUpdate(homeDir)
+	log.Infoln("geo databases updated")
+
+	runtime.GC()
+	debug.FreeOSMemory()
+
+	if geoOnSuccess != nil {
+		geoOnSuccess.OnGeoUpdateSuccess()
+	}
+	return nil
+}
+
+// geoDownloadClient bounds each geo database download so a stalled connection
+// can't wedge the updater forever (UpdateGeoDatabases only clears geoUpdating
+// once downloadToFile returns).
+var geoDownloadClient = &http.Client{Timeout: 30 * time.Second}
+
+func downloadToFile(url, dest string) error {
+	resp, err := geoDownloadClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("unexpected status downloading " + url + ": " + resp.Status)
+	}
+
+	tmp := dest + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, dest)
+}
+
+func touchLastUpdate(homeDir string) {
+	path := filepath.Join(homeDir, geoLastUpdateTag)
+	now := time.Now()
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		return
+	}
+	os.Chtimes(path, now, now)
+}
+
+func lastGeoUpdate(homeDir string) (time.Time, bool) {
+	info, err := os.Stat(filepath.Join(homeDir, geoLastUpdateTag))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return info.ModTime(), true
+}
+
+// startGeoUpdater launches the background ticker goroutine. Called from StartProxy.
+func startGeoUpdater() {
+	if geoTicker != nil {
+		return
+	}
+	interval := time.Duration(geoIntervalHours) * time.Hour
+	geoTicker = time.NewTicker(interval)
+	geoStopCh = make(chan struct{})
+	stopCh := geoStopCh
+	ticker := geoTicker
+
+	go func() {
+		homeDir := constant.Path.HomeDir()
+		if last, ok := lastGeoUpdate(homeDir); ok && time.Since(last) < interval {
+			// Restarted recently; don't re-download immediately.
+		} else if err := UpdateGeoDatabases(); err != nil && !errors.Is(err, ErrGeoUpdateSkip) {
+			log.Warnln("initial geo database update failed: %s", err.Error())
+		}
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := UpdateGeoDatabases(); err != nil && !errors.Is(err, ErrGeoUpdateSkip) {
+					log.Warnln("scheduled geo database update failed: %s", err.Error())
+				}
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// stopGeoUpdater stops the background ticker goroutine. Called from StopProxy.
+func stopGeoUpdater() {
+	if geoTicker == nil {
+		return
+	}
+	geoTicker.Stop()
+	close(geoStopCh)
+	geoTicker = nil
+	geoStopCh = nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2026 Max Lv <max.c.lv@gmail.com>
+//
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package bridge
+
+import (
+	"github.com/metacubex/mihomo/component/resolver"
+	"github.com/metacubex/mihomo/constant/provider"
+	"github.com/metacubex/mihomo/log"
+	"github.com/metacubex/mihomo/tunnel"
+)
+
+// pruneFakeIPPool flushes cached fake-ip assignments so they can be garbage
+// collected, at the cost of clients needing to re-resolve affected hostnames.
+func pruneFakeIPPool() {
+	pool := resolver.FakeIPPool
+	if pool == nil {
+		return
+	}
+	pool.FlushFakeIP()
+}
+
+// dropInactiveProviderCaches closes providers that are not part of the
+// currently selected proxy chain, releasing their cached proxy lists and
+// healthcheck state. An inactive provider re-opens lazily on its next Update.
+func dropInactiveProviderCaches() {
+	for _, p := range tunnel.ProxyProviders() {
+		name := p.Name()
+		if providerIsActive(p) {
+			continue
+		}
+
+		closer, ok := any(p).(interface{ Close() error })
+		if !ok {
+			continue
+		}
+		if err := closer.Close(); err != nil {
+			log.Debugln("failed to drop cache for inactive provider %s: %s", name, err.Error())
+			continue
+		}
+		log.Debugln("dropped cache for inactive provider %s", name)
+	}
+}
+
+// providerIsActive reports whether any proxy group currently has one of this
+// provider's own proxies selected, i.e. the provider backs the live chain.
+func providerIsActive(p provider.ProxyProvider) bool {
+	memberNames := make(map[string]struct{})
+	for _, px := range p.Proxies() {
+		memberNames[px.Name()] = struct{}{}
+	}
+
+	for _, proxy := range tunnel.Proxies() {
+		group, ok := proxy.(proxyGroup)
+		if !ok {
+			continue
+		}
+		if _, selected := memberNames[group.Now()]; selected {
+			return true
+		}
+	}
+	return false
+}
+
+// closeIdleProxyConnections tears down idle proxy dialer connections to free
+// memory under critical pressure, at the cost of a reconnect on next use.
+func closeIdleProxyConnections() {
+	for _, proxy := range tunnel.Proxies() {
+		closer, ok := proxy.(interface{ CloseIdleConnections() })
+		if !ok {
+			continue
+		}
+		closer.CloseIdleConnections()
+	}
+}
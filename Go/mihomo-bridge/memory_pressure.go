@@ -0,0 +1,123 @@
+// Copyright (c) 2026 Max Lv <max.c.lv@gmail.com>
+//
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package bridge
+
+import (
+	"math"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// defaultGCPercent matches the aggressive GOGC set in init() for the 15MB
+// Network Extension budget; NotifyMemoryPressure restores it once pressure
+// drops back to normal.
+const defaultGCPercent = 10
+
+// Memory pressure levels reported by the iOS host via NotifyMemoryPressure.
+const (
+	MemoryPressureNormal   int32 = 0
+	MemoryPressureWarning  int32 = 1
+	MemoryPressureCritical int32 = 2
+)
+
+var (
+	memMu          sync.Mutex
+	memBudgetMB    int32 = 15
+	oomCallback    func()
+	oomMonitorOnce sync.Once
+	oomMonitorStop chan struct{}
+)
+
+// SetMemoryBudgetMB sets the extension's total memory budget in MB. This is
+// used to derive the debug.SetMemoryLimit ceiling and the OOM callback
+// threshold. Defaults to 15MB, matching NEPacketTunnelProvider's jetsam limit.
+func SetMemoryBudgetMB(budgetMB int32) {
+	memMu.Lock()
+	memBudgetMB = budgetMB
+	memMu.Unlock()
+}
+
+// GetMemoryUsage returns current heap allocation, heap system reservation, and
+// an approximation of resident set size (heapSys is used as a stand-in on iOS,
+// where gopsutil/process is stubbed out; see patches/gopsutil).
+func GetMemoryUsage() (heapAlloc, heapSys, rss int64) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return int64(stats.HeapAlloc), int64(stats.HeapSys), int64(stats.HeapSys)
+}
+
+// NotifyMemoryPressure reacts to an iOS memory-pressure signal (didReceiveMemoryWarning
+// or jetsam pressure). level 0=normal, 1=warning, 2=critical. Under warning the
+// runtime is squeezed aggressively; under critical idle proxy connections are
+// also torn down. Pressure easing back to normal restores the prior GC settings
+// rather than leaving the runtime permanently squeezed.
+func NotifyMemoryPressure(level int32) {
+	memMu.Lock()
+	budget := memBudgetMB
+	memMu.Unlock()
+
+	if level <= MemoryPressureNormal {
+		debug.SetMemoryLimit(math.MaxInt64)
+		runtime.SetGCPercent(defaultGCPercent)
+		return
+	}
+
+	debug.SetMemoryLimit(int64(float64(budget) * 0.8 * 1024 * 1024))
+	runtime.SetGCPercent(1)
+	debug.FreeOSMemory()
+
+	pruneFakeIPPool()
+	dropInactiveProviderCaches()
+
+	if level >= MemoryPressureCritical {
+		closeIdleProxyConnections()
+	}
+}
+
+// RegisterOOMCallback registers a callback invoked from a background monitor
+// when HeapAlloc exceeds 90% of the configured memory budget, giving Swift a
+// chance to preemptively degrade quality (e.g. disable UDP relay) before the
+// OS kills the extension. The monitor samples runtime.MemStats every 500ms and
+// starts lazily on first registration for the life of the process.
+func RegisterOOMCallback(callback func()) {
+	memMu.Lock()
+	oomCallback = callback
+	memMu.Unlock()
+
+	oomMonitorOnce.Do(func() {
+		oomMonitorStop = make(chan struct{})
+		go oomMonitor(oomMonitorStop)
+	})
+}
+
+func oomMonitor(stop chan struct{}) {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			memMu.Lock()
+			budget := memBudgetMB
+			callback := oomCallback
+			memMu.Unlock()
+
+			if callback == nil {
+				continue
+			}
+
+			var stats runtime.MemStats
+			runtime.ReadMemStats(&stats)
+			threshold := uint64(float64(budget) * 0.9 * 1024 * 1024)
+			if stats.HeapAlloc > threshold {
+				callback()
+			}
+		}
+	}
+}
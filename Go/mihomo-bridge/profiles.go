@@ -0,0 +1,180 @@
+// Copyright (c) 2026 Max Lv <max.c.lv@gmail.com>
+//
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package bridge
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/metacubex/mihomo/config"
+	"github.com/metacubex/mihomo/constant"
+	"github.com/metacubex/mihomo/hub/executor"
+	"github.com/metacubex/mihomo/log"
+)
+
+var (
+	profileMu     sync.Mutex
+	activeProfile string
+)
+
+const profilesDirName = "profiles"
+
+func profilesDir() string {
+	return filepath.Join(constant.Path.HomeDir(), profilesDirName)
+}
+
+// validProfileName rejects empty names and anything that isn't a bare file
+// name, so callers can't escape profilesDir() via path separators or "..".
+func validProfileName(name string) bool {
+	return name != "" && filepath.Base(name) == name && name != "." && name != ".."
+}
+
+func profilePath(name string) string {
+	return filepath.Join(profilesDir(), name+".yaml")
+}
+
+// SaveProfile validates and writes a named YAML profile under <home>/profiles.
+func SaveProfile(name, yaml string) error {
+	if !validProfileName(name) {
+		return fmt.Errorf("invalid profile name %q", name)
+	}
+	if _, err := config.Parse([]byte(yaml)); err != nil {
+		return fmt.Errorf("invalid profile config: %w", err)
+	}
+
+	if err := os.MkdirAll(profilesDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create profiles directory: %w", err)
+	}
+	return os.WriteFile(profilePath(name), []byte(yaml), 0644)
+}
+
+// ListProfiles returns a JSON array of saved profile names.
+func ListProfiles() string {
+	entries, err := os.ReadDir(profilesDir())
+	if err != nil {
+		return "[]"
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".yaml") {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yaml"))
+	}
+	sort.Strings(names)
+
+	data, err := json.Marshal(names)
+	if err != nil {
+		return "[]"
+	}
+	return string(data)
+}
+
+// DeleteProfile removes a saved profile. The active profile cannot be deleted
+// and returns an error; call ActivateProfile with another name first.
+func DeleteProfile(name string) error {
+	if !validProfileName(name) {
+		return fmt.Errorf("invalid profile name %q", name)
+	}
+
+	profileMu.Lock()
+	if activeProfile == name {
+		profileMu.Unlock()
+		return fmt.Errorf("cannot delete the active profile %q", name)
+	}
+	profileMu.Unlock()
+
+	if err := os.Remove(profilePath(name)); err != nil {
+		return fmt.Errorf("failed to delete profile %q: %w", name, err)
+	}
+	return nil
+}
+
+// ActivateProfile parses the named profile and, if the engine is running, hot-swaps
+// it in without tearing down the TUN listener. Call StartProxy first if the engine
+// has not started yet; ActivateProfile only updates the on-disk active config and
+// the live tunnel state.
+func ActivateProfile(name string) error {
+	if !validProfileName(name) {
+		return fmt.Errorf("invalid profile name %q", name)
+	}
+
+	data, err := os.ReadFile(profilePath(name))
+	if err != nil {
+		return fmt.Errorf("failed to read profile %q: %w", name, err)
+	}
+
+	cfg, err := config.Parse(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse profile %q: %w", name, err)
+	}
+
+	mu.Lock()
+	if tunFdGlobal >= 0 {
+		cfg.Tun.Enable = true
+		cfg.Tun.FileDescriptor = int(tunFdGlobal)
+		cfg.Tun.AutoRoute = false
+		cfg.Tun.AutoDetectInterface = false
+	}
+	if running {
+		executor.ApplyConfig(cfg, false)
+	}
+	mu.Unlock()
+
+	homeDir := constant.Path.HomeDir()
+	if err := os.WriteFile(filepath.Join(homeDir, "config.yaml"), data, 0644); err != nil {
+		return fmt.Errorf("failed to persist active config: %w", err)
+	}
+
+	profileMu.Lock()
+	activeProfile = name
+	profileMu.Unlock()
+
+	log.Infoln("activated profile %s", name)
+	return nil
+}
+
+// HotReloadConfig re-parses and re-applies the currently active config.yaml
+// without resetting the TUN listener, useful after an external edit. Returns
+// an error if the engine has not been started with StartProxy.
+func HotReloadConfig() error {
+	homeDir := constant.Path.HomeDir()
+	cfg, err := executor.Parse()
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if !running {
+		return fmt.Errorf("proxy is not running")
+	}
+
+	if tunFdGlobal >= 0 {
+		cfg.Tun.Enable = true
+		cfg.Tun.FileDescriptor = int(tunFdGlobal)
+		cfg.Tun.AutoRoute = false
+		cfg.Tun.AutoDetectInterface = false
+	}
+
+	executor.ApplyConfig(cfg, false)
+	log.Infoln("hot reloaded config from %s", homeDir)
+	return nil
+}
+
+// ActiveProfile returns the name of the currently activated profile, or an
+// empty string if none has been activated via ActivateProfile.
+func ActiveProfile() string {
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	return activeProfile
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2026 Max Lv <max.c.lv@gmail.com>
+//
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/metacubex/mihomo/adapter/outboundgroup"
+	"github.com/metacubex/mihomo/constant"
+	"github.com/metacubex/mihomo/tunnel"
+)
+
+// proxyGroup is implemented by Mihomo's outbound group adapters (Selector,
+// URLTest, Fallback, LoadBalance, Relay) and lets us enumerate members without
+// going through the external HTTP controller.
+type proxyGroup interface {
+	Now() string
+	GetProxies(touch bool) []constant.Proxy
+}
+
+type proxyGroupInfo struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Now     string   `json:"now"`
+	Members []string `json:"members"`
+}
+
+// ListProxyGroups returns a JSON array describing every proxy group: name, type,
+// currently selected member, and the list of member names.
+func ListProxyGroups() string {
+	proxies := tunnel.Proxies()
+
+	groups := make([]proxyGroupInfo, 0)
+	for name, proxy := range proxies {
+		group, ok := proxy.(proxyGroup)
+		if !ok {
+			continue
+		}
+
+		members := group.GetProxies(false)
+		memberNames := make([]string, 0, len(members))
+		for _, m := range members {
+			memberNames = append(memberNames, m.Name())
+		}
+
+		groups = append(groups, proxyGroupInfo{
+			Name:    name,
+			Type:    proxy.Type().String(),
+			Now:     group.Now(),
+			Members: memberNames,
+		})
+	}
+
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+
+	data, err := json.Marshal(groups)
+	if err != nil {
+		return "[]"
+	}
+	return string(data)
+}
+
+// SelectProxy sets the active member of a Selector-type proxy group.
+func SelectProxy(group, name string) error {
+	proxy, ok := tunnel.Proxies()[group]
+	if !ok {
+		return fmt.Errorf("proxy group %q not found", group)
+	}
+
+	selector, ok := proxy.(*outboundgroup.Selector)
+	if !ok {
+		return fmt.Errorf("proxy group %q is not selectable", group)
+	}
+
+	return selector.Set(name)
+}
+
+// TestLatency measures round-trip latency in milliseconds for a single proxy
+// against testURL, bounded by timeoutMs.
+func TestLatency(name, testURL string, timeoutMs int) (int64, error) {
+	proxy, ok := tunnel.Proxies()[name]
+	if !ok {
+		return 0, fmt.Errorf("proxy %q not found", name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutMs)*time.Millisecond)
+	defer cancel()
+
+	delay, err := proxy.URLTest(ctx, testURL)
+	if err != nil {
+		return 0, err
+	}
+	return int64(delay), nil
+}
+
+type groupLatencyResult struct {
+	DelayMs int64  `json:"delayMs,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// TestGroupLatency concurrently tests every member of a proxy group against
+// testURL, bounded by timeoutMs per member and at most concurrency members in
+// flight at once. Returns a JSON object mapping member name to {delayMs|error}.
+func TestGroupLatency(group, testURL string, timeoutMs int, concurrency int) string {
+	proxy, ok := tunnel.Proxies()[group]
+	if !ok {
+		return "{}"
+	}
+	pg, ok := proxy.(proxyGroup)
+	if !ok {
+		return "{}"
+	}
+
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	members := pg.GetProxies(false)
+	results := make(map[string]groupLatencyResult, len(members))
+	var resultsMu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	for _, m := range members {
+		m := m
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutMs)*time.Millisecond)
+			defer cancel()
+
+			delay, err := m.URLTest(ctx, testURL)
+
+			resultsMu.Lock()
+			if err != nil {
+				results[m.Name()] = groupLatencyResult{Error: err.Error()}
+			} else {
+				results[m.Name()] = groupLatencyResult{DelayMs: int64(delay)}
+			}
+			resultsMu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	data, err := json.Marshal(results)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
@@ -0,0 +1,207 @@
+// Copyright (c) 2026 Max Lv <max.c.lv@gmail.com>
+//
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/metacubex/mihomo/log"
+	"github.com/metacubex/mihomo/tunnel"
+)
+
+// LogSink receives log events streamed from the Mihomo engine.
+type LogSink interface {
+	OnLog(level, payload string)
+}
+
+// TrafficSink receives periodic samples of the cumulative upload/download byte
+// totals.
+type TrafficSink interface {
+	OnTraffic(up, down int64)
+}
+
+// ConnectionSink receives connection lifecycle events as JSON.
+type ConnectionSink interface {
+	OnConnection(eventJSON string)
+}
+
+const sinkChannelDepth = 64
+
+var (
+	sinkMu         sync.Mutex
+	logSink        LogSink
+	trafficSink    TrafficSink
+	connectionSink ConnectionSink
+	trafficTickMs  int64 = 1000
+	sinkCtx        context.Context
+	sinkCancel     context.CancelFunc
+)
+
+// SetLogSink registers the sink that receives log events. Pass nil to disable.
+func SetLogSink(sink LogSink) {
+	sinkMu.Lock()
+	logSink = sink
+	sinkMu.Unlock()
+}
+
+// SetTrafficSink registers the sink that receives traffic samples, and the
+// sampling interval in milliseconds (default 1000 if tickMs <= 0).
+func SetTrafficSink(sink TrafficSink, tickMs int64) {
+	sinkMu.Lock()
+	trafficSink = sink
+	if tickMs > 0 {
+		trafficTickMs = tickMs
+	} else {
+		trafficTickMs = 1000
+	}
+	sinkMu.Unlock()
+}
+
+// SetConnectionSink registers the sink that receives connection events. Pass
+// nil to disable.
+func SetConnectionSink(sink ConnectionSink) {
+	sinkMu.Lock()
+	connectionSink = sink
+	sinkMu.Unlock()
+}
+
+type connectionEvent struct {
+	Type string `json:"type"` // "add" or "close"
+	ID   string `json:"id"`
+}
+
+// startSinks launches the log/traffic/connection forwarding goroutines. Called
+// from StartProxy; the goroutines exit when StopProxy cancels their context.
+func startSinks() {
+	sinkCtx, sinkCancel = context.WithCancel(context.Background())
+	ctx := sinkCtx
+
+	go forwardLogs(ctx)
+	go forwardTraffic(ctx)
+	go forwardConnections(ctx)
+}
+
+// stopSinks cancels the forwarding goroutines. Called from StopProxy.
+func stopSinks() {
+	if sinkCancel != nil {
+		sinkCancel()
+		sinkCancel = nil
+	}
+}
+
+// forwardLogs relays log events to the registered LogSink through a bounded
+// buffer so a blocking Swift callback can never stall the receive loop off
+// log.Subscribe(), which would back up into Mihomo's log subscriber channel
+// and starve the tunnel goroutine. Events are dropped once the buffer is full.
+func forwardLogs(ctx context.Context) {
+	sub := log.Subscribe()
+	defer log.UnSubscribe(sub)
+
+	buffered := make(chan log.Event, sinkChannelDepth)
+	go deliverLogs(ctx, buffered)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub:
+			if !ok {
+				return
+			}
+			select {
+			case buffered <- event:
+			default:
+				// Drop under back-pressure rather than blocking on Mihomo's subscriber channel.
+			}
+		}
+	}
+}
+
+// deliverLogs runs the (potentially slow) sink callback on its own goroutine,
+// decoupled from the receive loop in forwardLogs.
+func deliverLogs(ctx context.Context, events <-chan log.Event) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-events:
+			sinkMu.Lock()
+			sink := logSink
+			sinkMu.Unlock()
+			if sink == nil {
+				continue
+			}
+			sink.OnLog(event.LogLevel.String(), event.Payload)
+		}
+	}
+}
+
+func forwardTraffic(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(trafficTickMs) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sinkMu.Lock()
+			sink := trafficSink
+			sinkMu.Unlock()
+			if sink == nil {
+				continue
+			}
+			snapshot := tunnel.DefaultManager.Snapshot()
+			sink.OnTraffic(snapshot.UploadTotal, snapshot.DownloadTotal)
+		}
+	}
+}
+
+func forwardConnections(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	seen := make(map[string]struct{})
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sinkMu.Lock()
+			sink := connectionSink
+			sinkMu.Unlock()
+			if sink == nil {
+				continue
+			}
+
+			current := make(map[string]struct{})
+			for _, c := range tunnel.DefaultManager.Snapshot().Connections {
+				id := c.ID()
+				current[id] = struct{}{}
+				if _, ok := seen[id]; !ok {
+					emitConnectionEvent(sink, "add", id)
+				}
+			}
+			for id := range seen {
+				if _, ok := current[id]; !ok {
+					emitConnectionEvent(sink, "close", id)
+				}
+			}
+			seen = current
+		}
+	}
+}
+
+func emitConnectionEvent(sink ConnectionSink, kind, id string) {
+	data, err := json.Marshal(connectionEvent{Type: kind, ID: id})
+	if err != nil {
+		return
+	}
+	sink.OnConnection(string(data))
+}
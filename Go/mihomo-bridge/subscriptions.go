@@ -0,0 +1,326 @@
+// Copyright (c) 2026 Max Lv <max.c.lv@gmail.com>
+//
+// Licensed under the MIT License. See LICENSE file in the project root for details.
+
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/metacubex/mihomo/component/dialer"
+	"github.com/metacubex/mihomo/config"
+	"github.com/metacubex/mihomo/constant"
+	"github.com/metacubex/mihomo/log"
+)
+
+// SubscriptionSink receives a notification whenever a subscribed profile is
+// refreshed, so Swift can prompt the user to activate the new profile.
+type SubscriptionSink interface {
+	OnSubscriptionUpdated(name string)
+}
+
+type subscriptionMeta struct {
+	Name                string    `json:"name"`
+	URL                 string    `json:"url"`
+	UserAgent           string    `json:"userAgent"`
+	UpdateIntervalHours int       `json:"updateIntervalHours"`
+	LastUpdate          time.Time `json:"lastUpdate"`
+	Upload              int64     `json:"upload"`
+	Download            int64     `json:"download"`
+	Total               int64     `json:"total"`
+	Expire              int64     `json:"expire"`
+}
+
+var (
+	subMu            sync.Mutex
+	subscriptionSink SubscriptionSink
+	subTicker        *time.Ticker
+	subStopCh        chan struct{}
+)
+
+// directClient fetches subscriptions through dialer.DialContext, the same
+// direct-dialer Mihomo's "direct" outbound uses, so the request goes out the
+// real network interface instead of looping back through the TUN fd once the
+// engine is running.
+var directClient = &http.Client{
+	Timeout: 30 * time.Second,
+	Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, addr)
+		},
+	},
+}
+
+// validSubscriptionName rejects empty names and anything that isn't a bare
+// file name, so callers can't escape subscriptionsDir() via path separators
+// or "..".
+func validSubscriptionName(name string) bool {
+	return name != "" && filepath.Base(name) == name && name != "." && name != ".."
+}
+
+const subscriptionsDirName = "subscriptions"
+
+func subscriptionsDir() string {
+	return filepath.Join(constant.Path.HomeDir(), subscriptionsDirName)
+}
+
+func subscriptionMetaPath(name string) string {
+	return filepath.Join(subscriptionsDir(), name+".json")
+}
+
+func subscriptionYAMLPath(name string) string {
+	return filepath.Join(subscriptionsDir(), name+".yaml")
+}
+
+// SetSubscriptionSink registers the sink notified after each successful
+// subscription refresh. Pass nil to disable.
+func SetSubscriptionSink(sink SubscriptionSink) {
+	subMu.Lock()
+	subscriptionSink = sink
+	subMu.Unlock()
+}
+
+// AddSubscription registers a new subscription and performs an initial fetch.
+func AddSubscription(name, url, userAgent string, updateIntervalHours int) error {
+	if !validSubscriptionName(name) {
+		return fmt.Errorf("invalid subscription name %q", name)
+	}
+	if url == "" {
+		return fmt.Errorf("subscription url is required")
+	}
+	if updateIntervalHours <= 0 {
+		updateIntervalHours = 24
+	}
+
+	if err := os.MkdirAll(subscriptionsDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create subscriptions directory: %w", err)
+	}
+
+	meta := subscriptionMeta{
+		Name:                name,
+		URL:                 url,
+		UserAgent:           userAgent,
+		UpdateIntervalHours: updateIntervalHours,
+	}
+	if err := writeSubscriptionMeta(meta); err != nil {
+		return err
+	}
+
+	return RefreshSubscription(name)
+}
+
+// RefreshSubscription downloads the subscription YAML via the direct dialer
+// (bypassing the proxy to avoid loops), validates it, persists it and updated
+// traffic metadata, and notifies the registered SubscriptionSink on success.
+func RefreshSubscription(name string) error {
+	if !validSubscriptionName(name) {
+		return fmt.Errorf("invalid subscription name %q", name)
+	}
+
+	meta, err := readSubscriptionMeta(name)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, meta.URL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request for subscription %q: %w", name, err)
+	}
+	if meta.UserAgent != "" {
+		req.Header.Set("User-Agent", meta.UserAgent)
+	}
+
+	resp, err := directClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch subscription %q: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("subscription %q returned status %s", name, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read subscription %q: %w", name, err)
+	}
+
+	if _, err := config.Parse(body); err != nil {
+		return fmt.Errorf("subscription %q returned invalid config: %w", name, err)
+	}
+
+	if err := os.WriteFile(subscriptionYAMLPath(name), body, 0644); err != nil {
+		return fmt.Errorf("failed to persist subscription %q: %w", name, err)
+	}
+
+	parseSubscriptionUserinfo(resp.Header.Get("Subscription-Userinfo"), &meta)
+	meta.LastUpdate = time.Now()
+	if err := writeSubscriptionMeta(meta); err != nil {
+		return err
+	}
+
+	log.Infoln("refreshed subscription %s", name)
+
+	subMu.Lock()
+	sink := subscriptionSink
+	subMu.Unlock()
+	if sink != nil {
+		sink.OnSubscriptionUpdated(name)
+	}
+	return nil
+}
+
+// parseSubscriptionUserinfo fills traffic quota fields from the widely-used
+// Subscription-Userinfo header: "upload=…; download=…; total=…; expire=…".
+func parseSubscriptionUserinfo(header string, meta *subscriptionMeta) {
+	if header == "" {
+		return
+	}
+	for _, part := range strings.Split(header, ";") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		value, err := strconv.ParseInt(strings.TrimSpace(kv[1]), 10, 64)
+		if err != nil {
+			continue
+		}
+		switch strings.TrimSpace(kv[0]) {
+		case "upload":
+			meta.Upload = value
+		case "download":
+			meta.Download = value
+		case "total":
+			meta.Total = value
+		case "expire":
+			meta.Expire = value
+		}
+	}
+}
+
+// GetSubscriptionInfo returns a subscription's metadata, including traffic
+// quotas, as JSON.
+func GetSubscriptionInfo(name string) string {
+	if !validSubscriptionName(name) {
+		return "{}"
+	}
+	meta, err := readSubscriptionMeta(name)
+	if err != nil {
+		return "{}"
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// RemoveSubscription deletes a subscription's metadata and fetched config.
+func RemoveSubscription(name string) error {
+	if !validSubscriptionName(name) {
+		return fmt.Errorf("invalid subscription name %q", name)
+	}
+	if err := os.Remove(subscriptionMetaPath(name)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove subscription %q: %w", name, err)
+	}
+	if err := os.Remove(subscriptionYAMLPath(name)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove subscription config %q: %w", name, err)
+	}
+	return nil
+}
+
+func readSubscriptionMeta(name string) (subscriptionMeta, error) {
+	var meta subscriptionMeta
+	data, err := os.ReadFile(subscriptionMetaPath(name))
+	if err != nil {
+		return meta, fmt.Errorf("subscription %q not found: %w", name, err)
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return meta, fmt.Errorf("corrupt metadata for subscription %q: %w", name, err)
+	}
+	return meta, nil
+}
+
+func writeSubscriptionMeta(meta subscriptionMeta) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata for subscription %q: %w", meta.Name, err)
+	}
+	return os.WriteFile(subscriptionMetaPath(meta.Name), data, 0644)
+}
+
+func listSubscriptionNames() []string {
+	entries, err := os.ReadDir(subscriptionsDir())
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	return names
+}
+
+// startSubscriptionUpdater launches the background ticker that refreshes due
+// subscriptions. Called from StartProxy.
+func startSubscriptionUpdater() {
+	if subTicker != nil {
+		return
+	}
+	subTicker = time.NewTicker(time.Hour)
+	subStopCh = make(chan struct{})
+	ticker := subTicker
+	stopCh := subStopCh
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				refreshDueSubscriptions()
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// stopSubscriptionUpdater stops the background ticker. Called from StopProxy.
+func stopSubscriptionUpdater() {
+	if subTicker == nil {
+		return
+	}
+	subTicker.Stop()
+	close(subStopCh)
+	subTicker = nil
+	subStopCh = nil
+}
+
+func refreshDueSubscriptions() {
+	for _, name := range listSubscriptionNames() {
+		meta, err := readSubscriptionMeta(name)
+		if err != nil {
+			continue
+		}
+		interval := time.Duration(meta.UpdateIntervalHours) * time.Hour
+		if time.Since(meta.LastUpdate) < interval {
+			continue
+		}
+		if err := RefreshSubscription(name); err != nil {
+			log.Warnln("failed to refresh subscription %s: %s", name, err.Error())
+		}
+	}
+}